@@ -0,0 +1,78 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceIPPool looks up an existing IPPool by name and exposes the same
+// attributes as resourceIPPool, so operators can adopt pools created outside
+// of Terraform (by calicoctl or the operator installation) without hand
+// crafting state.
+func dataSourceIPPool() *schema.Resource {
+	resourceSchema := resourceCalicoIPPoolSchemaV3()
+	makeSchemaComputed(resourceSchema)
+
+	metadata := resourceSchema["metadata"]
+	metadata.Required = true
+	metadata.Computed = false
+
+	name := metadata.Elem.(*schema.Resource).Schema["name"]
+	name.Required = true
+	name.Optional = false
+	name.Computed = false
+
+	return &schema.Resource{
+		ReadContext: dataSourceIPPoolRead,
+		Schema:      resourceSchema,
+	}
+}
+
+// makeSchemaComputed recursively turns every Required/Optional field of a
+// resource schema into a read-only Computed one, so it can be reused as the
+// schema of a companion data source. Callers then re-open whichever fields
+// (typically a lookup key) the data source still needs as input.
+func makeSchemaComputed(s map[string]*schema.Schema) {
+	for _, v := range s {
+		v.Required = false
+		v.Optional = false
+		v.Computed = true
+		v.ForceNew = false
+		v.Default = nil
+		v.ValidateFunc = nil
+		v.ConflictsWith = nil
+
+		if res, ok := v.Elem.(*schema.Resource); ok {
+			makeSchemaComputed(res.Schema)
+		}
+	}
+}
+
+func dataSourceIPPoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("metadata.0.name").(string)
+	logID := fmt.Sprintf("[dataSourceIPPoolRead: %s]", name)
+	debug("%s Started", logID)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	r, err := getIPPool(ctx, m, p, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setResourceAttributes(d, r); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+func dataSourceIPPoolUtilization() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIPPoolUtilizationRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the IPPool to report utilization for.",
+			},
+			"total_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Total number of addresses covered by the pool's CIDR.",
+			},
+			"allocated_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of addresses currently allocated out of the pool.",
+			},
+			"free_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of addresses still available for allocation.",
+			},
+			"block_size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The CIDR size of allocation blocks used by this pool.",
+			},
+			"num_blocks": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of allocation blocks that have been carved out of the pool.",
+			},
+			"blocks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Per-block breakdown of allocations within the pool.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cidr": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"affinity": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The node the block is affine to, empty if unaffine.",
+						},
+						"in_use": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"free": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"borrowed": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True when at least one address in the block is allocated to a node other than the block's affine node (i.e. handed out by borrowing from another node's block).",
+						},
+					},
+				},
+			},
+			"by_node": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "In-use address counts summed by the node the block is affine to.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+func dataSourceIPPoolUtilizationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("name").(string)
+	logID := fmt.Sprintf("[dataSourceIPPoolUtilizationRead: %s]", name)
+	debug("%s Started", logID)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pool, err := getIPPool(ctx, m, p, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, poolNet, err := net.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("%s could not parse pool cidr %q: %w", logID, pool.Spec.CIDR, err))
+	}
+
+	bc, err := m.GetCalicoBackendClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list, err := bc.List(ctx, model.BlockListOptions{IPVersion: ipVersion(pool.Spec.CIDR)}, "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	totalAddresses := cidrAddressCount(poolNet)
+
+	var (
+		allocatedAddresses int
+		blocks             []map[string]interface{}
+		byNode             = map[string]interface{}{}
+	)
+
+	for _, kv := range list.KVPairs {
+		key, ok := kv.Key.(model.BlockKey)
+		if !ok {
+			continue
+		}
+		if !poolNet.Contains(key.CIDR.IPNet.IP) {
+			continue
+		}
+
+		block := kv.Value.(*model.AllocationBlock)
+
+		blockSize := block.NumAddresses()
+		inUse := blockSize - len(block.Unallocated)
+
+		affinity := ""
+		if block.Affinity != nil {
+			affinity = strings.TrimPrefix(*block.Affinity, "host:")
+		}
+
+		allocatedAddresses += inUse
+
+		blocks = append(blocks, map[string]interface{}{
+			"cidr":     key.CIDR.String(),
+			"affinity": affinity,
+			"in_use":   inUse,
+			"free":     blockSize - inUse,
+			"borrowed": blockHasBorrowedAllocations(block, affinity),
+		})
+
+		if affinity != "" {
+			current := 0
+			if v, ok := byNode[affinity]; ok {
+				current = v.(int)
+			}
+			byNode[affinity] = current + inUse
+		}
+	}
+
+	d.SetId(name)
+	if err := d.Set("total_addresses", totalAddresses); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("allocated_addresses", allocatedAddresses); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("free_addresses", totalAddresses-allocatedAddresses); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("block_size", pool.Spec.BlockSize); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("num_blocks", len(blocks)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("blocks", blocks); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("by_node", byNode); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
+
+// cidrAddressCount returns the number of addresses covered by n, derived
+// purely from its prefix length. Blocks are allocated on demand (see the
+// block_size doc comment in resource_ippool.go), so this must NOT be
+// computed by summing the blocks that happen to exist yet, or a freshly
+// created or lightly used pool would falsely report itself as exhausted.
+// Clamped to math.MaxInt32 for very large pools (e.g. a /32 IPv6 pool),
+// whose true address count the Terraform SDK's TypeInt can't represent.
+func cidrAddressCount(n *net.IPNet) int {
+	ones, bits := n.Mask.Size()
+	count := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	if count.IsInt64() && count.Int64() <= math.MaxInt32 {
+		return int(count.Int64())
+	}
+	return math.MaxInt32
+}
+
+// blockHasBorrowedAllocations reports whether the block contains an address
+// allocated to a node other than affinityHost, which happens when IPAM
+// borrows addresses from a block another node is affine to (or, if the
+// block itself has no affinity, any allocation in it is by definition
+// borrowed).
+func blockHasBorrowedAllocations(block *model.AllocationBlock, affinityHost string) bool {
+	for _, idx := range block.Allocations {
+		if idx == nil {
+			continue
+		}
+
+		if affinityHost == "" {
+			return true
+		}
+
+		attr := block.Attributes[*idx]
+		if host, ok := attr.AttrSecondary["node"]; ok && host != "" && host != affinityHost {
+			return true
+		}
+	}
+	return false
+}
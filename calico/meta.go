@@ -0,0 +1,70 @@
+package calico
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	clientset "github.com/projectcalico/api/pkg/client/clientset_generated/clientset"
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"k8s.io/client-go/rest"
+)
+
+var debugEnabled = os.Getenv("TF_LOG") != ""
+
+func debug(format string, args ...interface{}) {
+	if !debugEnabled {
+		return
+	}
+	log.Printf("[DEBUG] "+format, args...)
+}
+
+// Meta holds the provider-wide state shared across resources and data
+// sources: the Calico clientset, the raw datastore backend client, and a
+// mutex serializing access since the Calico API server does not support
+// concurrent writes safely.
+type Meta struct {
+	sync.Mutex
+
+	restConfig *rest.Config
+	apiConfig  *apiconfig.CalicoAPIConfig
+
+	clientset *clientset.Clientset
+	backend   bapi.Client
+}
+
+// GetCalicoConfiguration returns the clientset used to talk to the
+// projectcalico.org/v3 API, lazily creating it from the provider's REST
+// config on first use.
+func (m *Meta) GetCalicoConfiguration() (*clientset.Clientset, error) {
+	if m.clientset != nil {
+		return m.clientset, nil
+	}
+
+	cs, err := clientset.NewForConfig(m.restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	m.clientset = cs
+	return m.clientset, nil
+}
+
+// GetCalicoBackendClient returns the raw libcalico-go datastore client,
+// used for data that is not exposed as a projectcalico.org/v3 resource,
+// such as IPAM allocation blocks.
+func (m *Meta) GetCalicoBackendClient() (bapi.Client, error) {
+	if m.backend != nil {
+		return m.backend, nil
+	}
+
+	bc, err := backend.NewClient(*m.apiConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	m.backend = bc
+	return m.backend, nil
+}
@@ -0,0 +1,70 @@
+package calico
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Provider returns the terraform-provider-calico schema.Provider, wiring
+// the resources and data sources backed by the projectcalico.org/v3 API.
+func Provider() *schema.Provider {
+	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"kubeconfig": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KUBECONFIG", nil),
+				Description: "Path to a kubeconfig used to reach the Calico API server. Defaults to the KUBECONFIG environment variable, then in-cluster config.",
+			},
+			"kubecontext": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Context to use from the kubeconfig. Defaults to the kubeconfig's current context.",
+			},
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			"calico_ippool":                         resourceIPPool(),
+			"calico_bgp_configuration":              resourceBGPConfiguration(),
+			"calico_ip_reservation":                 resourceIPReservation(),
+			"calico_kube_controllers_configuration": resourceKubeControllersConfiguration(),
+			"calico_ip_pool_slice":                  resourceIPPoolSlice(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"calico_ippool":             dataSourceIPPool(),
+			"calico_ippool_utilization": dataSourceIPPoolUtilization(),
+		},
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig, ok := d.GetOk("kubeconfig"); ok {
+		loadingRules.ExplicitPath = kubeconfig.(string)
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if kubecontext, ok := d.GetOk("kubecontext"); ok {
+		overrides.CurrentContext = kubecontext.(string)
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	apiConfig, err := apiconfig.LoadClientConfig("")
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	return &Meta{
+		restConfig: restConfig,
+		apiConfig:  apiConfig,
+	}, nil
+}
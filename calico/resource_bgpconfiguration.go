@@ -0,0 +1,359 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	calicov3 "github.com/projectcalico/api/pkg/apis/projectcalico/v3"
+	clientset "github.com/projectcalico/api/pkg/client/clientset_generated/clientset"
+	"github.com/projectcalico/api/pkg/lib/numorstring"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func resourceBGPConfiguration() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceBGPConfigurationCreate,
+		ReadContext:   resourceBGPConfigurationRead,
+		UpdateContext: resourceBGPConfigurationUpdate,
+		DeleteContext: resourceBGPConfigurationDelete,
+		Schema:        resourceCalicoBGPConfigurationSchemaV3(),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceCalicoBGPConfigurationSchemaV3() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"metadata": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MaxItems:    1,
+			Description: "BGPConfiguration Metadata.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						ForceNew:    true,
+						Description: "Name is the name of the BGPConfiguration. Use \"default\" for the cluster-wide configuration, or \"node.<nodename>\" to scope it to a single node.",
+					},
+					"resource_version": {
+						Type:        schema.TypeString,
+						Description: "An opaque value that represents the internal version",
+						Computed:    true,
+					},
+					"annotations": {
+						Type:         schema.TypeMap,
+						Description:  "An unstructured key value map",
+						Optional:     true,
+						Elem:         &schema.Schema{Type: schema.TypeString},
+						ValidateFunc: validateAnnotations,
+					},
+				},
+			},
+		},
+		"spec": {
+			Type:        schema.TypeList,
+			Description: "Spec defines the desired state of the BGP configuration. More info: https://projectcalico.docs.tigera.io/reference/resources/bgpconfig",
+			Required:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"node_to_node_mesh_enabled": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Computed:    true,
+						Description: "When set to false, disables the BGP full node-to-node mesh.",
+					},
+					"as_number": {
+						Type:        schema.TypeInt,
+						Optional:    true,
+						Computed:    true,
+						Description: "The default local AS number that Calico should use when speaking with BGP peers.",
+					},
+					"service_cluster_ips": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "CIDR ranges for Kubernetes Service ClusterIPs that should be advertised over BGP.",
+						Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateCIDR},
+					},
+					"service_external_ips": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "CIDR ranges for Kubernetes Service ExternalIPs that should be advertised over BGP.",
+						Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateCIDR},
+					},
+					"listen_port": {
+						Type:        schema.TypeInt,
+						Optional:    true,
+						Description: "The port that BIRD listens on for BGP connections. Defaults to 179.",
+					},
+					"log_severity_screen": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "Info",
+						Description:  "The log severity above which logs are sent to the BGP daemon's stdout.",
+						ValidateFunc: validation.StringInSlice([]string{"Debug", "Info", "Warning", "Error", "Fatal", "None"}, false),
+					},
+				},
+			},
+		},
+	}
+}
+
+func getBGPConfiguration(ctx context.Context, m *Meta, p *clientset.Clientset, name string) (*calicov3.BGPConfiguration, error) {
+	debug("%s getBGPConfiguration wait for lock", name)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s getBGPConfiguration got lock, started", name)
+
+	r, err := p.ProjectcalicoV3().BGPConfigurations().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		debug("getBGPConfiguration for %s errored", name)
+		return nil, err
+	}
+
+	debug("%s getBGPConfiguration done", name)
+
+	return r, nil
+}
+
+func setBGPConfigurationResourceAttributes(d *schema.ResourceData, r *calicov3.BGPConfiguration) error {
+	d.SetId(r.Name)
+
+	metadata := []map[string]interface{}{{"name": r.Name, "resource_version": r.ResourceVersion, "annotations": r.Annotations}}
+	if err := d.Set("metadata", metadata); err != nil {
+		return err
+	}
+
+	asNumber := 0
+	if r.Spec.ASNumber != nil {
+		asNumber = int(*r.Spec.ASNumber)
+	}
+
+	nodeToNodeMeshEnabled := true
+	if r.Spec.NodeToNodeMeshEnabled != nil {
+		nodeToNodeMeshEnabled = *r.Spec.NodeToNodeMeshEnabled
+	}
+
+	spec := []map[string]interface{}{{
+		"node_to_node_mesh_enabled": nodeToNodeMeshEnabled,
+		"as_number":                 asNumber,
+		"service_cluster_ips":       flattenServiceCIDRs(r.Spec.ServiceClusterIPs),
+		"service_external_ips":      flattenServiceExternalCIDRs(r.Spec.ServiceExternalIPs),
+		"listen_port":               r.Spec.ListenPort,
+		"log_severity_screen":       r.Spec.LogSeverityScreen,
+	}}
+	if err := d.Set("spec", spec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func flattenServiceCIDRs(blocks []calicov3.ServiceClusterIPBlock) []string {
+	result := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		result = append(result, b.CIDR)
+	}
+	return result
+}
+
+func flattenServiceExternalCIDRs(blocks []calicov3.ServiceExternalIPBlock) []string {
+	result := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		result = append(result, b.CIDR)
+	}
+	return result
+}
+
+func expandServiceCIDRs(cidrs []interface{}) []calicov3.ServiceClusterIPBlock {
+	result := make([]calicov3.ServiceClusterIPBlock, 0, len(cidrs))
+	for _, c := range cidrs {
+		result = append(result, calicov3.ServiceClusterIPBlock{CIDR: c.(string)})
+	}
+	return result
+}
+
+func expandServiceExternalCIDRs(cidrs []interface{}) []calicov3.ServiceExternalIPBlock {
+	result := make([]calicov3.ServiceExternalIPBlock, 0, len(cidrs))
+	for _, c := range cidrs {
+		result = append(result, calicov3.ServiceExternalIPBlock{CIDR: c.(string)})
+	}
+	return result
+}
+
+// nodeToNodeMeshEnabledSetInConfig reports whether the user actually wrote
+// spec.0.node_to_node_mesh_enabled in their configuration. node_to_node_mesh_enabled
+// is Optional+Computed, so the deprecated d.GetOkExists is unreliable here;
+// inspect the raw config instead, the same way blockSizeSetInConfig does in
+// resource_ippool.go.
+func nodeToNodeMeshEnabledSetInConfig(d *schema.ResourceData) bool {
+	cfg := d.GetRawConfig()
+	if cfg.IsNull() || !cfg.IsKnown() {
+		return false
+	}
+
+	spec := cfg.GetAttr("spec")
+	if spec.IsNull() || !spec.IsKnown() || spec.LengthInt() == 0 {
+		return false
+	}
+
+	v := spec.Index(cty.NumberIntVal(0)).GetAttr("node_to_node_mesh_enabled")
+	return !v.IsNull()
+}
+
+func setBGPConfigurationAttributes(d *schema.ResourceData, r *calicov3.BGPConfiguration) error {
+	spec := calicov3.BGPConfigurationSpec{}
+
+	if nodeToNodeMeshEnabledSetInConfig(d) {
+		nodeToNodeMeshEnabled := d.Get("spec.0.node_to_node_mesh_enabled").(bool)
+		spec.NodeToNodeMeshEnabled = &nodeToNodeMeshEnabled
+	}
+
+	if asNumber := d.Get("spec.0.as_number").(int); asNumber != 0 {
+		asn := numorstring.ASNumber(asNumber)
+		spec.ASNumber = &asn
+	}
+
+	spec.ServiceClusterIPs = expandServiceCIDRs(d.Get("spec.0.service_cluster_ips").([]interface{}))
+	spec.ServiceExternalIPs = expandServiceExternalCIDRs(d.Get("spec.0.service_external_ips").([]interface{}))
+	spec.ListenPort = uint16(d.Get("spec.0.listen_port").(int))
+	spec.LogSeverityScreen = d.Get("spec.0.log_severity_screen").(string)
+
+	r.Name = d.Get("metadata.0.name").(string)
+	r.ResourceVersion = d.Get("metadata.0.resource_version").(string)
+	r.Annotations = expandStringMap(d.Get("metadata.0.annotations").(map[string]interface{}))
+	r.Spec = spec
+
+	return nil
+}
+
+func resourceBGPConfigurationExists(ctx context.Context, d *schema.ResourceData, meta interface{}) (bool, error) {
+	logID := fmt.Sprintf("[resourceBGPConfigurationExists: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Start", logID)
+
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = getBGPConfiguration(ctx, m, p, name)
+
+	debug("%s Done", logID)
+
+	if err == nil {
+		return true, nil
+	}
+
+	return false, err
+}
+
+func resourceBGPConfigurationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	exists, err := resourceBGPConfigurationExists(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if !exists {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	logID := fmt.Sprintf("[resourceBGPConfigurationRead: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Started", logID)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := d.Get("metadata.0.name").(string)
+	r, err := getBGPConfiguration(ctx, m, p, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setBGPConfigurationResourceAttributes(d, r); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
+
+func resourceBGPConfigurationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	bgpConfig := calicov3.BGPConfiguration{}
+	if err := setBGPConfigurationAttributes(d, &bgpConfig); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().BGPConfigurations().Create(ctx, &bgpConfig, metav1.CreateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(bgpConfig.Name)
+
+	return nil
+}
+
+func resourceBGPConfigurationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	bgpConfig := calicov3.BGPConfiguration{}
+	if err := setBGPConfigurationAttributes(d, &bgpConfig); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().BGPConfigurations().Update(ctx, &bgpConfig, metav1.UpdateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(bgpConfig.Name)
+
+	return nil
+}
+
+func resourceBGPConfigurationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = p.ProjectcalicoV3().BGPConfigurations().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,492 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	calicov3 "github.com/projectcalico/api/pkg/apis/projectcalico/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ipPoolSliceCIDRsAnnotation records the full set of sub-CIDRs that have
+	// been sliced out of a parent_pool so far, as a comma-separated list of
+	// canonical CIDRs. It is the source of truth for recomputing the shared
+	// IPReservation's ReservedCIDRs whenever a sibling slice is added or
+	// removed.
+	ipPoolSliceCIDRsAnnotation = "ippoolslice.projectcalico.org/cidrs"
+
+	// ipPoolSliceNamespaceAnnotationPrefix is suffixed with a sanitized,
+	// canonical CIDR to namespace each slice's own projectcalico.org/namespace
+	// value within the IPReservation shared by every slice of the same pool.
+	ipPoolSliceNamespaceAnnotationPrefix = "projectcalico.org/namespace."
+)
+
+// resourceIPPoolSlice lets a team be handed a sub-CIDR of a larger, shared
+// IPPool without the pool owner having to split the pool itself. All slices
+// declared against the same parent_pool share a single IPReservation, named
+// after the pool, whose ReservedCIDRs is the complement of the *union* of
+// every declared slice — not just this one — so that sibling slices don't
+// each reserve away the CIDR the others are trying to keep free. The slice
+// is tagged with the namespaces allowed to select it.
+func resourceIPPoolSlice() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIPPoolSliceCreate,
+		ReadContext:   resourceIPPoolSliceRead,
+		UpdateContext: resourceIPPoolSliceUpdate,
+		DeleteContext: resourceIPPoolSliceDelete,
+		Schema:        resourceCalicoIPPoolSliceSchemaV3(),
+	}
+}
+
+func resourceCalicoIPPoolSliceSchemaV3() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"parent_pool": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Description: "Name of the existing calico_ippool this slice is carved out of.",
+		},
+		"cidr": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validateCIDR,
+			Description:  "The sub-CIDR to reserve exclusively for this slice. Must be contained within parent_pool's CIDR and must not overlap any other calico_ip_pool_slice of the same parent_pool.",
+		},
+		"namespaces": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Namespaces allowed to select this slice, recorded as a projectcalico.org/namespace.<cidr> annotation (with / and : in the CIDR replaced by -) on the shared IPReservation, for use in namespaceSelector expressions.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"reservation_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the IPReservation shared by every slice of parent_pool, used to fence off whatever isn't sliced out yet.",
+		},
+		"reservation_cidrs": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "The CIDRs currently reserved within parent_pool to leave only the declared slices available for IPAM.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// splitCIDR divides n into its two equal child CIDRs.
+func splitCIDR(n *net.IPNet) (*net.IPNet, *net.IPNet) {
+	ones, bits := n.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+
+	lower := n.IP.Mask(mask)
+
+	upper := make(net.IP, len(lower))
+	copy(upper, lower)
+	byteIndex := ones / 8
+	bitIndex := uint(7 - ones%8)
+	upper[byteIndex] |= 1 << bitIndex
+
+	return &net.IPNet{IP: lower, Mask: mask}, &net.IPNet{IP: upper, Mask: mask}
+}
+
+// complementCIDRs returns the minimal set of CIDRs covering parent minus
+// hole. hole must be contained within parent and share its address family.
+func complementCIDRs(parent, hole *net.IPNet) ([]*net.IPNet, error) {
+	parentOnes, parentBits := parent.Mask.Size()
+	holeOnes, holeBits := hole.Mask.Size()
+
+	if parentBits != holeBits {
+		return nil, fmt.Errorf("cidr %s and parent_pool cidr %s are not the same address family", hole, parent)
+	}
+	if holeOnes < parentOnes || !parent.Contains(hole.IP) {
+		return nil, fmt.Errorf("cidr %s is not contained within parent_pool cidr %s", hole, parent)
+	}
+
+	var result []*net.IPNet
+	cur := &net.IPNet{IP: parent.IP.Mask(parent.Mask), Mask: parent.Mask}
+	for {
+		curOnes, _ := cur.Mask.Size()
+		if curOnes >= holeOnes {
+			return result, nil
+		}
+
+		left, right := splitCIDR(cur)
+		if left.Contains(hole.IP) {
+			result = append(result, right)
+			cur = left
+		} else {
+			result = append(result, left)
+			cur = right
+		}
+	}
+}
+
+// complementOfCIDRs returns the minimal set of CIDRs covering parent minus
+// every CIDR in holes. Each hole must be contained within parent and must
+// not overlap any other hole.
+func complementOfCIDRs(parent *net.IPNet, holes []*net.IPNet) ([]*net.IPNet, error) {
+	fragments := []*net.IPNet{parent}
+
+	for _, hole := range holes {
+		next := make([]*net.IPNet, 0, len(fragments)+1)
+		replaced := false
+
+		for _, frag := range fragments {
+			if !replaced && frag.Contains(hole.IP) {
+				pieces, err := complementCIDRs(frag, hole)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, pieces...)
+				replaced = true
+				continue
+			}
+			next = append(next, frag)
+		}
+
+		if !replaced {
+			return nil, fmt.Errorf("cidr %s is not contained within parent_pool %s, or overlaps another calico_ip_pool_slice", hole, parent)
+		}
+
+		fragments = next
+	}
+
+	return fragments, nil
+}
+
+func ipPoolSliceReservationName(parentPool string) string {
+	return fmt.Sprintf("%s-slices", parentPool)
+}
+
+// canonicalCIDR parses cidr and returns both the parsed network and its
+// canonical string form (lowercase, network address only), so that
+// case-insensitive-but-distinct spellings of the same CIDR (e.g. an IPv6
+// address in uppercase hex) always produce the same registry entry,
+// annotation key and Kubernetes object data.
+func canonicalCIDR(cidr string) (*net.IPNet, string, error) {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, "", err
+	}
+	return n, n.String(), nil
+}
+
+func sanitizeCIDRKey(canonicalCIDR string) string {
+	return strings.NewReplacer("/", "-", ":", "-").Replace(canonicalCIDR)
+}
+
+// parseCIDRRegistry returns the canonical CIDRs of every slice currently
+// declared against a shared IPReservation, read back from
+// ipPoolSliceCIDRsAnnotation.
+func parseCIDRRegistry(reservation *calicov3.IPReservation) []string {
+	if reservation.Annotations == nil {
+		return nil
+	}
+
+	v := reservation.Annotations[ipPoolSliceCIDRsAnnotation]
+	if v == "" {
+		return nil
+	}
+
+	return strings.Split(v, ",")
+}
+
+// ipPoolSliceReservedCIDRs recomputes the ReservedCIDRs needed to fence off
+// every CIDR in registry from parentNet.
+func ipPoolSliceReservedCIDRs(parentNet *net.IPNet, registry []string) ([]string, error) {
+	holes := make([]*net.IPNet, 0, len(registry))
+	for _, c := range registry {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		holes = append(holes, n)
+	}
+
+	fragments, err := complementOfCIDRs(parentNet, holes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		result = append(result, f.String())
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func ipPoolSliceNamespaceAnnotationKey(sliceCIDR string) string {
+	return ipPoolSliceNamespaceAnnotationPrefix + sanitizeCIDRKey(sliceCIDR)
+}
+
+func resourceIPPoolSliceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parentPool := d.Get("parent_pool").(string)
+	cidr := d.Get("cidr").(string)
+	namespaces := expandStringList(d.Get("namespaces").([]interface{}))
+
+	pool, err := getIPPool(ctx, m, p, parentPool)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, parentNet, err := net.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("parent_pool %q has an invalid cidr %q: %w", parentPool, pool.Spec.CIDR, err))
+	}
+
+	_, sliceCIDR, err := canonicalCIDR(cidr)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := ipPoolSliceReservationName(parentPool)
+	logID := fmt.Sprintf("[resourceIPPoolSliceCreate: %s]", name)
+
+	debug("%s wait for lock", logID)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s got lock, started", logID)
+
+	existing, err := p.ProjectcalicoV3().IPReservations().Get(ctx, name, metav1.GetOptions{})
+	notFound := apierrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return diag.FromErr(err)
+	}
+
+	reservation := existing
+	var registry []string
+	if notFound {
+		reservation = &calicov3.IPReservation{}
+		reservation.Name = name
+	} else {
+		registry = parseCIDRRegistry(reservation)
+		for _, c := range registry {
+			if c == sliceCIDR {
+				return diag.FromErr(fmt.Errorf("cidr %s is already sliced out of parent_pool %s", sliceCIDR, parentPool))
+			}
+		}
+	}
+	registry = append(registry, sliceCIDR)
+
+	reservedCIDRs, err := ipPoolSliceReservedCIDRs(parentNet, registry)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	reservation.Spec.ReservedCIDRs = reservedCIDRs
+
+	if reservation.Annotations == nil {
+		reservation.Annotations = map[string]string{}
+	}
+	reservation.Annotations[ipPoolSliceCIDRsAnnotation] = strings.Join(registry, ",")
+	if len(namespaces) > 0 {
+		reservation.Annotations[ipPoolSliceNamespaceAnnotationKey(sliceCIDR)] = strings.Join(namespaces, ",")
+	}
+
+	if notFound {
+		_, err = p.ProjectcalicoV3().IPReservations().Create(ctx, reservation, metav1.CreateOptions{})
+	} else {
+		_, err = p.ProjectcalicoV3().IPReservations().Update(ctx, reservation, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s done", logID)
+
+	d.SetId(parentPool + "/" + sliceCIDR)
+
+	return resourceIPPoolSliceRead(ctx, d, meta)
+}
+
+func resourceIPPoolSliceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parentPool := d.Get("parent_pool").(string)
+	_, sliceCIDR, err := canonicalCIDR(d.Get("cidr").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := ipPoolSliceReservationName(parentPool)
+	logID := fmt.Sprintf("[resourceIPPoolSliceRead: %s]", name)
+	debug("%s Started", logID)
+
+	reservation, err := getIPReservation(ctx, m, p, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			d.SetId("")
+			return diag.Diagnostics{}
+		}
+		return diag.FromErr(err)
+	}
+
+	registered := false
+	for _, c := range parseCIDRRegistry(reservation) {
+		if c == sliceCIDR {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set("reservation_name", reservation.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("reservation_cidrs", reservation.Spec.ReservedCIDRs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
+
+func resourceIPPoolSliceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parentPool := d.Get("parent_pool").(string)
+	_, sliceCIDR, err := canonicalCIDR(d.Get("cidr").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	namespaces := expandStringList(d.Get("namespaces").([]interface{}))
+
+	name := ipPoolSliceReservationName(parentPool)
+	logID := fmt.Sprintf("[resourceIPPoolSliceUpdate: %s]", name)
+
+	debug("%s wait for lock", logID)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s got lock, started", logID)
+
+	reservation, err := p.ProjectcalicoV3().IPReservations().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	namespaceKey := ipPoolSliceNamespaceAnnotationKey(sliceCIDR)
+	if reservation.Annotations == nil {
+		reservation.Annotations = map[string]string{}
+	}
+	if len(namespaces) > 0 {
+		reservation.Annotations[namespaceKey] = strings.Join(namespaces, ",")
+	} else {
+		delete(reservation.Annotations, namespaceKey)
+	}
+
+	_, err = p.ProjectcalicoV3().IPReservations().Update(ctx, reservation, metav1.UpdateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s done", logID)
+
+	return resourceIPPoolSliceRead(ctx, d, meta)
+}
+
+func resourceIPPoolSliceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parentPool := d.Get("parent_pool").(string)
+	_, sliceCIDR, err := canonicalCIDR(d.Get("cidr").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pool, err := getIPPool(ctx, m, p, parentPool)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	name := ipPoolSliceReservationName(parentPool)
+	logID := fmt.Sprintf("[resourceIPPoolSliceDelete: %s]", name)
+
+	debug("%s wait for lock", logID)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s got lock, started", logID)
+
+	reservation, err := p.ProjectcalicoV3().IPReservations().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	registry := parseCIDRRegistry(reservation)
+	remaining := registry[:0:0]
+	for _, c := range registry {
+		if c != sliceCIDR {
+			remaining = append(remaining, c)
+		}
+	}
+
+	if len(remaining) == 0 {
+		if err := p.ProjectcalicoV3().IPReservations().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			return diag.FromErr(err)
+		}
+		debug("%s done, reservation deleted", logID)
+		return nil
+	}
+
+	if pool == nil {
+		return diag.FromErr(fmt.Errorf("parent_pool %q no longer exists, cannot recompute reservation for remaining slices", parentPool))
+	}
+
+	_, parentNet, err := net.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("parent_pool %q has an invalid cidr %q: %w", parentPool, pool.Spec.CIDR, err))
+	}
+
+	reservedCIDRs, err := ipPoolSliceReservedCIDRs(parentNet, remaining)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	reservation.Spec.ReservedCIDRs = reservedCIDRs
+	reservation.Annotations[ipPoolSliceCIDRsAnnotation] = strings.Join(remaining, ",")
+	delete(reservation.Annotations, ipPoolSliceNamespaceAnnotationKey(sliceCIDR))
+
+	if _, err := p.ProjectcalicoV3().IPReservations().Update(ctx, reservation, metav1.UpdateOptions{}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s done, reservation updated", logID)
+
+	return nil
+}
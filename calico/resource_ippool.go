@@ -3,8 +3,10 @@ package calico
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -22,8 +24,19 @@ var defaultAttributes = map[string]interface{}{
 	"nat_outgoing":       false,
 	"disabled":           false,
 	"disable_bgp_export": false,
+	"assignment_mode":    "Automatic",
 }
 
+const (
+	ipv4DefaultBlockSize = 26
+	ipv6DefaultBlockSize = 122
+
+	ipv4MinBlockSize = 20
+	ipv4MaxBlockSize = 32
+	ipv6MinBlockSize = 116
+	ipv6MaxBlockSize = 128
+)
+
 func resourceIPPool() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceIPPoolCreate,
@@ -31,7 +44,122 @@ func resourceIPPool() *schema.Resource {
 		UpdateContext: resourceIPPoolUpdate,
 		DeleteContext: resourceIPPoolDelete,
 		Schema:        resourceCalicoIPPoolSchemaV3(),
+		CustomizeDiff: resourceIPPoolCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceIPPoolCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if err := validateIPPoolAssignmentMode(diff); err != nil {
+		return err
+	}
+
+	return validateIPPoolCIDRFamily(diff)
+}
+
+func validateIPPoolAssignmentMode(diff *schema.ResourceDiff) error {
+	mode := diff.Get("spec.0.assignment_mode").(string)
+	if mode != "Manual" {
+		return nil
+	}
+
+	uses := diff.Get("spec.0.allowed_uses").([]interface{})
+	for _, u := range uses {
+		if u.(string) == "LoadBalancer" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("spec.0.assignment_mode can only be set to \"Manual\" when spec.0.allowed_uses contains \"LoadBalancer\"")
+}
+
+// validateIPPoolCIDRFamily cross-validates block_size and ipip_mode against
+// the address family derived from cidr, and auto-defaults block_size to the
+// IPv6 value when the user left it unset on an IPv6 pool.
+func validateIPPoolCIDRFamily(diff *schema.ResourceDiff) error {
+	cidr := diff.Get("spec.0.cidr").(string)
+	_, parsed, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("spec.0.cidr: %s", err)
+	}
+
+	isIPv6 := parsed.IP.To4() == nil
+
+	if isIPv6 && diff.Get("spec.0.ipip_mode").(string) != "Never" {
+		return fmt.Errorf("spec.0.ipip_mode must be \"Never\" for IPv6 pools, Calico does not support IPIP over IPv6")
+	}
+
+	blockSizeKey := "spec.0.block_size"
+	if !diff.NewValueKnown(blockSizeKey) {
+		return nil
 	}
+
+	blockSize := diff.Get(blockSizeKey).(int)
+
+	return validateIPPoolBlockSize(diff, blockSizeKey, blockSize, isIPv6)
+}
+
+// blockSizeSetInConfig reports whether the user actually wrote spec.0.block_size
+// in their configuration, as opposed to it being filled in from the schema
+// default. This lets IPv6 auto-defaulting distinguish "left unset" from
+// "explicitly set to the IPv4 default on an IPv6 pool", the latter of which
+// should fail validation rather than be silently rewritten.
+func blockSizeSetInConfig(diff *schema.ResourceDiff) bool {
+	cfg := diff.GetRawConfig()
+	if cfg.IsNull() || !cfg.IsKnown() {
+		return false
+	}
+
+	spec := cfg.GetAttr("spec")
+	if spec.IsNull() || !spec.IsKnown() || spec.LengthInt() == 0 {
+		return false
+	}
+
+	blockSize := spec.Index(cty.NumberIntVal(0)).GetAttr("block_size")
+	return !blockSize.IsNull()
+}
+
+func validateIPPoolBlockSize(diff *schema.ResourceDiff, blockSizeKey string, blockSize int, isIPv6 bool) error {
+	if isIPv6 {
+		if blockSize == ipv4DefaultBlockSize && !blockSizeSetInConfig(diff) {
+			if err := diff.SetNew(blockSizeKey, ipv6DefaultBlockSize); err != nil {
+				return err
+			}
+			blockSize = ipv6DefaultBlockSize
+		}
+		if blockSize < ipv6MinBlockSize || blockSize > ipv6MaxBlockSize {
+			return fmt.Errorf("spec.0.block_size must be between %d and %d for IPv6 pools, got %d", ipv6MinBlockSize, ipv6MaxBlockSize, blockSize)
+		}
+		return nil
+	}
+
+	if blockSize < ipv4MinBlockSize || blockSize > ipv4MaxBlockSize {
+		return fmt.Errorf("spec.0.block_size must be between %d and %d for IPv4 pools, got %d", ipv4MinBlockSize, ipv4MaxBlockSize, blockSize)
+	}
+
+	return nil
+}
+
+func validateCIDR(value interface{}, key string) (ws []string, es []error) {
+	cidr := value.(string)
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		es = append(es, fmt.Errorf("%s (%q) is not a valid CIDR: %s", key, cidr, err))
+	}
+	return
+}
+
+// ipVersion returns 4 or 6 for a valid CIDR, or 0 if cidr cannot be parsed.
+func ipVersion(cidr string) int {
+	_, parsed, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0
+	}
+	if parsed.IP.To4() == nil {
+		return 6
+	}
+	return 4
 }
 
 func validateAnnotations(value interface{}, key string) (ws []string, es []error) {
@@ -85,9 +213,15 @@ func resourceCalicoIPPoolSchemaV3() map[string]*schema.Schema {
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					"cidr": {
-						Type:     schema.TypeString,
-						Required: true,
-						ForceNew: true,
+						Type:         schema.TypeString,
+						Required:     true,
+						ForceNew:     true,
+						ValidateFunc: validateCIDR,
+					},
+					"ip_version": {
+						Type:        schema.TypeInt,
+						Computed:    true,
+						Description: "The IP version (4 or 6) derived from cidr.",
 					},
 					"block_size": {
 						Type:        schema.TypeInt,
@@ -130,6 +264,23 @@ func resourceCalicoIPPoolSchemaV3() map[string]*schema.Schema {
 						Default:     defaultAttributes["disable_bgp_export"],
 						Description: "Disable exporting routes from this IP Poolâ€™s CIDR over BGP.",
 					},
+					"assignment_mode": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      defaultAttributes["assignment_mode"],
+						Description:  "AssignmentMode determines if IP addresses from this pool are assigned automatically by Calico IPAM, or only on request (Manual). Manual is only valid when allowed_uses contains LoadBalancer.",
+						ValidateFunc: validation.StringInSlice([]string{"Automatic", "Manual"}, false),
+					},
+					"allowed_uses": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Computed:    true,
+						Description: "AllowedUses controls what the IP pool will be used for. If not specified, defaults to [\"Workload\", \"Tunnel\"].",
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringInSlice([]string{"Workload", "Tunnel", "LoadBalancer"}, false),
+						},
+					},
 				},
 			},
 		},
@@ -164,11 +315,14 @@ func setResourceAttributes(d *schema.ResourceData, r *calicov3.IPPool) error {
 	spec := []map[string]interface{}{{
 		"block_size":         r.Spec.BlockSize,
 		"cidr":               r.Spec.CIDR,
+		"ip_version":         ipVersion(r.Spec.CIDR),
 		"disabled":           r.Spec.Disabled,
 		"ipip_mode":          r.Spec.IPIPMode,
 		"vxlan_mode":         r.Spec.VXLANMode,
 		"nat_outgoing":       r.Spec.NATOutgoing,
 		"disable_bgp_export": r.Spec.DisableBGPExport,
+		"assignment_mode":    flattenAssignmentMode(r.Spec.AssignmentMode),
+		"allowed_uses":       flattenIPPoolAllowedUses(r.Spec.AllowedUses),
 	}}
 	if err := d.Set("spec", spec); err != nil {
 		return err
@@ -193,6 +347,8 @@ func setIPPoolAttributes(d *schema.ResourceData, r *calicov3.IPPool) error {
 	spec.VXLANMode = getVXLANMode(d.Get("spec.0.vxlan_mode").(string))
 	spec.NATOutgoing = d.Get("spec.0.nat_outgoing").(bool)
 	spec.DisableBGPExport = d.Get("spec.0.disable_bgp_export").(bool)
+	spec.AssignmentMode = getAssignmentMode(d.Get("spec.0.assignment_mode").(string))
+	spec.AllowedUses = expandIPPoolAllowedUses(d.Get("spec.0.allowed_uses").([]interface{}))
 
 	r.Name = d.Get("metadata.0.name").(string)
 	r.ResourceVersion = d.Get("metadata.0.resource_version").(string)
@@ -276,6 +432,40 @@ func getVXLANMode(mode string) calicov3.VXLANMode {
 	return calicov3.VXLANModeNever
 }
 
+func getAssignmentMode(mode string) *calicov3.AssignmentMode {
+	var m calicov3.AssignmentMode
+	switch mode {
+	case "Manual":
+		m = calicov3.Manual
+	default:
+		m = calicov3.Automatic
+	}
+	return &m
+}
+
+func flattenAssignmentMode(mode *calicov3.AssignmentMode) string {
+	if mode == nil {
+		return defaultAttributes["assignment_mode"].(string)
+	}
+	return string(*mode)
+}
+
+func expandIPPoolAllowedUses(uses []interface{}) []calicov3.IPPoolAllowedUse {
+	result := make([]calicov3.IPPoolAllowedUse, 0, len(uses))
+	for _, u := range uses {
+		result = append(result, calicov3.IPPoolAllowedUse(u.(string)))
+	}
+	return result
+}
+
+func flattenIPPoolAllowedUses(uses []calicov3.IPPoolAllowedUse) []string {
+	result := make([]string, 0, len(uses))
+	for _, u := range uses {
+		result = append(result, string(u))
+	}
+	return result
+}
+
 func getIPIPMode(mode string) calicov3.IPIPMode {
 	switch mode {
 	case "Always":
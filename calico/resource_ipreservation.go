@@ -0,0 +1,256 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	calicov3 "github.com/projectcalico/api/pkg/apis/projectcalico/v3"
+	clientset "github.com/projectcalico/api/pkg/client/clientset_generated/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func resourceIPReservation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIPReservationCreate,
+		ReadContext:   resourceIPReservationRead,
+		UpdateContext: resourceIPReservationUpdate,
+		DeleteContext: resourceIPReservationDelete,
+		Schema:        resourceCalicoIPReservationSchemaV3(),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceCalicoIPReservationSchemaV3() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"metadata": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MaxItems:    1,
+			Description: "IPReservation Metadata.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						ForceNew:    true,
+						Computed:    true,
+						Description: "Name is the name of the IPReservation.",
+					},
+					"resource_version": {
+						Type:        schema.TypeString,
+						Description: "An opaque value that represents the internal version",
+						Computed:    true,
+					},
+					"annotations": {
+						Type:         schema.TypeMap,
+						Description:  "An unstructured key value map",
+						Optional:     true,
+						Elem:         &schema.Schema{Type: schema.TypeString},
+						ValidateFunc: validateAnnotations,
+					},
+				},
+			},
+		},
+		"spec": {
+			Type:        schema.TypeList,
+			Description: "Spec defines the desired state of the IPReservation. More info: https://projectcalico.docs.tigera.io/reference/resources/ipreservation",
+			Required:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"reserved_cidrs": {
+						Type:        schema.TypeList,
+						Required:    true,
+						MinItems:    1,
+						Description: "A list of CIDRs and/or IP addresses that Calico IPAM will exclude from assignment, without having to disable the whole IPPool they fall within.",
+						Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.Any(validation.IsIPAddress, validation.IsCIDR)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getIPReservation(ctx context.Context, m *Meta, p *clientset.Clientset, name string) (*calicov3.IPReservation, error) {
+	debug("%s getIPReservation wait for lock", name)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s getIPReservation got lock, started", name)
+
+	r, err := p.ProjectcalicoV3().IPReservations().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		debug("getIPReservation for %s errored", name)
+		return nil, err
+	}
+
+	debug("%s getIPReservation done", name)
+
+	return r, nil
+}
+
+func setIPReservationResourceAttributes(d *schema.ResourceData, r *calicov3.IPReservation) error {
+	d.SetId(r.Name)
+
+	metadata := []map[string]interface{}{{"name": r.Name, "resource_version": r.ResourceVersion, "annotations": r.Annotations}}
+	if err := d.Set("metadata", metadata); err != nil {
+		return err
+	}
+
+	spec := []map[string]interface{}{{
+		"reserved_cidrs": r.Spec.ReservedCIDRs,
+	}}
+	if err := d.Set("spec", spec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func expandStringList(l []interface{}) []string {
+	result := make([]string, 0, len(l))
+	for _, v := range l {
+		result = append(result, v.(string))
+	}
+	return result
+}
+
+func setIPReservationAttributes(d *schema.ResourceData, r *calicov3.IPReservation) error {
+	spec := calicov3.IPReservationSpec{}
+	spec.ReservedCIDRs = expandStringList(d.Get("spec.0.reserved_cidrs").([]interface{}))
+
+	r.Name = d.Get("metadata.0.name").(string)
+	r.ResourceVersion = d.Get("metadata.0.resource_version").(string)
+	r.Annotations = expandStringMap(d.Get("metadata.0.annotations").(map[string]interface{}))
+	r.Spec = spec
+
+	return nil
+}
+
+func resourceIPReservationExists(ctx context.Context, d *schema.ResourceData, meta interface{}) (bool, error) {
+	logID := fmt.Sprintf("[resourceIPReservationExists: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Start", logID)
+
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = getIPReservation(ctx, m, p, name)
+
+	debug("%s Done", logID)
+
+	if err == nil {
+		return true, nil
+	}
+
+	return false, err
+}
+
+func resourceIPReservationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	exists, err := resourceIPReservationExists(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if !exists {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	logID := fmt.Sprintf("[resourceIPReservationRead: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Started", logID)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := d.Get("metadata.0.name").(string)
+	r, err := getIPReservation(ctx, m, p, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setIPReservationResourceAttributes(d, r); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
+
+func resourceIPReservationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	reservation := calicov3.IPReservation{}
+	if err := setIPReservationAttributes(d, &reservation); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().IPReservations().Create(ctx, &reservation, metav1.CreateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(reservation.Name)
+
+	return nil
+}
+
+func resourceIPReservationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	reservation := calicov3.IPReservation{}
+	if err := setIPReservationAttributes(d, &reservation); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().IPReservations().Update(ctx, &reservation, metav1.UpdateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(reservation.Name)
+
+	return nil
+}
+
+func resourceIPReservationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = p.ProjectcalicoV3().IPReservations().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package calico
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	calicov3 "github.com/projectcalico/api/pkg/apis/projectcalico/v3"
+	clientset "github.com/projectcalico/api/pkg/client/clientset_generated/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func resourceKubeControllersConfiguration() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKubeControllersConfigurationCreate,
+		ReadContext:   resourceKubeControllersConfigurationRead,
+		UpdateContext: resourceKubeControllersConfigurationUpdate,
+		DeleteContext: resourceKubeControllersConfigurationDelete,
+		Schema:        resourceCalicoKubeControllersConfigurationSchemaV3(),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceCalicoKubeControllersConfigurationSchemaV3() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"metadata": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MaxItems:    1,
+			Description: "KubeControllersConfiguration Metadata. The name must be \"default\", Calico only ever reads the singleton resource of that name.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						ForceNew:    true,
+						Description: "Name is the name of the KubeControllersConfiguration, must be \"default\".",
+					},
+					"resource_version": {
+						Type:        schema.TypeString,
+						Description: "An opaque value that represents the internal version",
+						Computed:    true,
+					},
+					"annotations": {
+						Type:         schema.TypeMap,
+						Description:  "An unstructured key value map",
+						Optional:     true,
+						Elem:         &schema.Schema{Type: schema.TypeString},
+						ValidateFunc: validateAnnotations,
+					},
+				},
+			},
+		},
+		"spec": {
+			Type:        schema.TypeList,
+			Description: "Spec defines the desired state of the Calico kube-controllers. More info: https://projectcalico.docs.tigera.io/reference/resources/kubecontrollersconfig",
+			Required:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"controllers": {
+						Type:     schema.TypeList,
+						Required: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"node": {
+									Type:        schema.TypeList,
+									Optional:    true,
+									MaxItems:    1,
+									Description: "Configuration for the node controller.",
+									Elem: &schema.Resource{
+										Schema: map[string]*schema.Schema{
+											"reconciler_period": {
+												Type:        schema.TypeString,
+												Optional:    true,
+												Default:     "5m",
+												Description: "How often the node controller performs a full reconciliation of Kubernetes nodes against Calico node/BGP/route state, expressed as a Go duration string.",
+											},
+										},
+									},
+								},
+								"load_balancer": {
+									Type:        schema.TypeList,
+									Optional:    true,
+									MaxItems:    1,
+									Description: "Configuration for the load-balancer controller, which allocates addresses for Service type=LoadBalancer from LoadBalancer-use IPPools.",
+									Elem: &schema.Resource{
+										Schema: map[string]*schema.Schema{
+											"assign_ips": {
+												Type:         schema.TypeString,
+												Optional:     true,
+												Default:      "AllServices",
+												Description:  "Controls which Service type=LoadBalancer get an address assigned: every such Service (AllServices), or only those that explicitly request Calico IPAM (RequestedServicesOnly).",
+												ValidateFunc: validation.StringInSlice([]string{"AllServices", "RequestedServicesOnly"}, false),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getKubeControllersConfiguration(ctx context.Context, m *Meta, p *clientset.Clientset, name string) (*calicov3.KubeControllersConfiguration, error) {
+	debug("%s getKubeControllersConfiguration wait for lock", name)
+	m.Lock()
+	defer m.Unlock()
+	debug("%s getKubeControllersConfiguration got lock, started", name)
+
+	r, err := p.ProjectcalicoV3().KubeControllersConfigurations().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		debug("getKubeControllersConfiguration for %s errored", name)
+		return nil, err
+	}
+
+	debug("%s getKubeControllersConfiguration done", name)
+
+	return r, nil
+}
+
+func setKubeControllersConfigurationResourceAttributes(d *schema.ResourceData, r *calicov3.KubeControllersConfiguration) error {
+	d.SetId(r.Name)
+
+	metadata := []map[string]interface{}{{"name": r.Name, "resource_version": r.ResourceVersion, "annotations": r.Annotations}}
+	if err := d.Set("metadata", metadata); err != nil {
+		return err
+	}
+
+	node := []map[string]interface{}{}
+	if n := r.Spec.Controllers.Node; n != nil && n.ReconcilerPeriod != nil {
+		node = []map[string]interface{}{{"reconciler_period": n.ReconcilerPeriod.Duration.String()}}
+	}
+
+	loadBalancer := []map[string]interface{}{}
+	if r.Spec.Controllers.LoadBalancer != nil {
+		loadBalancer = []map[string]interface{}{{"assign_ips": string(r.Spec.Controllers.LoadBalancer.AssignIPs)}}
+	}
+
+	controllers := []map[string]interface{}{{
+		"node":          node,
+		"load_balancer": loadBalancer,
+	}}
+
+	spec := []map[string]interface{}{{
+		"controllers": controllers,
+	}}
+	if err := d.Set("spec", spec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func setKubeControllersConfigurationAttributes(d *schema.ResourceData, r *calicov3.KubeControllersConfiguration) error {
+	spec := calicov3.KubeControllersConfigurationSpec{}
+
+	if v, ok := d.GetOk("spec.0.controllers.0.node.0.reconciler_period"); ok {
+		reconcilerPeriod, err := time.ParseDuration(v.(string))
+		if err != nil {
+			return fmt.Errorf("spec.0.controllers.0.node.0.reconciler_period: %w", err)
+		}
+		spec.Controllers.Node = &calicov3.NodeControllerConfig{
+			ReconcilerPeriod: &metav1.Duration{Duration: reconcilerPeriod},
+		}
+	}
+
+	if _, ok := d.GetOk("spec.0.controllers.0.load_balancer.0.assign_ips"); ok {
+		spec.Controllers.LoadBalancer = &calicov3.LoadBalancerControllerConfig{
+			AssignIPs: calicov3.AssignIPs(d.Get("spec.0.controllers.0.load_balancer.0.assign_ips").(string)),
+		}
+	}
+
+	r.Name = d.Get("metadata.0.name").(string)
+	r.ResourceVersion = d.Get("metadata.0.resource_version").(string)
+	r.Annotations = expandStringMap(d.Get("metadata.0.annotations").(map[string]interface{}))
+	r.Spec = spec
+
+	return nil
+}
+
+func resourceKubeControllersConfigurationExists(ctx context.Context, d *schema.ResourceData, meta interface{}) (bool, error) {
+	logID := fmt.Sprintf("[resourceKubeControllersConfigurationExists: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Start", logID)
+
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = getKubeControllersConfiguration(ctx, m, p, name)
+
+	debug("%s Done", logID)
+
+	if err == nil {
+		return true, nil
+	}
+
+	return false, err
+}
+
+func resourceKubeControllersConfigurationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	exists, err := resourceKubeControllersConfigurationExists(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if !exists {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	logID := fmt.Sprintf("[resourceKubeControllersConfigurationRead: %s]", d.Get("metadata.0.name").(string))
+	debug("%s Started", logID)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := d.Get("metadata.0.name").(string)
+	r, err := getKubeControllersConfiguration(ctx, m, p, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setKubeControllersConfigurationResourceAttributes(d, r); err != nil {
+		return diag.FromErr(err)
+	}
+
+	debug("%s Done", logID)
+
+	return nil
+}
+
+func resourceKubeControllersConfigurationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	kcc := calicov3.KubeControllersConfiguration{}
+	if err := setKubeControllersConfigurationAttributes(d, &kcc); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().KubeControllersConfigurations().Create(ctx, &kcc, metav1.CreateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(kcc.Name)
+
+	return nil
+}
+
+func resourceKubeControllersConfigurationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	kcc := calicov3.KubeControllersConfiguration{}
+	if err := setKubeControllersConfigurationAttributes(d, &kcc); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = p.ProjectcalicoV3().KubeControllersConfigurations().Update(ctx, &kcc, metav1.UpdateOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(kcc.Name)
+
+	return nil
+}
+
+func resourceKubeControllersConfigurationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("metadata.0.name").(string)
+
+	m := meta.(*Meta)
+
+	p, err := m.GetCalicoConfiguration()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = p.ProjectcalicoV3().KubeControllersConfigurations().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}